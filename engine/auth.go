@@ -0,0 +1,22 @@
+package engine
+
+// Auth is a registry credential for Domain.
+type Auth struct {
+	Domain   string
+	Username string
+	Password string
+}
+
+// LookupAuth returns the credential configured on spec for domain, if
+// any.
+func LookupAuth(spec *Spec, domain string) (Auth, bool) {
+	if spec.Docker == nil {
+		return Auth{}, false
+	}
+	for _, auth := range spec.Docker.Auths {
+		if auth.Domain == domain {
+			return auth, true
+		}
+	}
+	return Auth{}, false
+}
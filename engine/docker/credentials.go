@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/drone/drone-runtime/engine"
+)
+
+// dockerConfig is the subset of ~/.docker/config.json this package
+// understands: the credsStore/credHelpers indirection used by
+// credential helper binaries. Plaintext "auths" entries are not read.
+type dockerConfig struct {
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// credentialCandidates returns every credential that might authorize
+// a pull against domain: the one embedded in the spec, followed by
+// any resolved through a configured Docker credential helper.
+func credentialCandidates(spec *engine.Spec, domain string) []engine.Auth {
+	var candidates []engine.Auth
+	if auth, ok := engine.LookupAuth(spec, domain); ok {
+		candidates = append(candidates, auth)
+	}
+	if auth, ok := lookupCredentialHelper(domain); ok {
+		candidates = append(candidates, auth)
+	}
+	return candidates
+}
+
+// lookupCredentialHelper resolves a credential for domain via the
+// credsStore or credHelpers entries of ~/.docker/config.json.
+func lookupCredentialHelper(domain string) (engine.Auth, bool) {
+	config, ok := loadDockerConfig()
+	if !ok {
+		return engine.Auth{}, false
+	}
+
+	helper := config.CredHelpers[domain]
+	if helper == "" {
+		helper = config.CredsStore
+	}
+	if helper == "" {
+		return engine.Auth{}, false
+	}
+
+	return execCredentialHelper(helper, domain)
+}
+
+// execCredentialHelper invokes the docker-credential-<helper> binary
+// per the credential helper protocol and decodes its response.
+func execCredentialHelper(helper, domain string) (engine.Auth, bool) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = bytes.NewBufferString(domain)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return engine.Auth{}, false
+	}
+
+	var resp struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return engine.Auth{}, false
+	}
+	return engine.Auth{Username: resp.Username, Password: resp.Secret}, true
+}
+
+// loadDockerConfig reads the credsStore/credHelpers indirection from
+// the default Docker config path.
+func loadDockerConfig() (*dockerConfig, bool) {
+	path := filepath.Join(os.Getenv("HOME"), ".docker", "config.json")
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, false
+	}
+	return &config, true
+}
@@ -5,11 +5,10 @@ import (
 	"context"
 	"errors"
 	"io"
-	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/drone/drone-runtime/engine"
-	"github.com/drone/drone-runtime/engine/docker/authutil"
 	"github.com/drone/drone-runtime/engine/docker/stdcopy"
 
 	"docker.io/go-docker"
@@ -18,24 +17,56 @@ import (
 	"github.com/docker/distribution/reference"
 )
 
+func init() {
+	engine.RegisterDriver("docker", New)
+}
+
+// New returns a new engine.Engine that creates, starts, stops and
+// tails containers using the Docker API. It is registered with the
+// engine package under the "docker" driver name and is selected at
+// process startup via the pipeline's configured backend.
+func New(spec *engine.Spec) (engine.Engine, error) {
+	cli, err := docker.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+	return &dockerEngine{
+		spec:    spec,
+		client:  cli,
+		pullSem: make(chan struct{}, pullConcurrency(spec)),
+	}, nil
+}
+
 type dockerEngine struct {
 	spec   *engine.Spec
 	client docker.APIClient
+
+	// pullSem bounds how many ImagePull calls this engine may have in
+	// flight at once, across all of its steps.
+	pullSem chan struct{}
 }
 
 func (e *dockerEngine) Setup(ctx context.Context) error {
 	if e.spec.Docker != nil {
-		// creates the default temporary (local) volumes
-		// that are mounted into each container step.
+		// creates the volumes that are mounted into each container
+		// step. Host path bind mounts need no daemon-side object, and
+		// external volumes are expected to already exist, so only
+		// emptyDir and named volumes are created here.
 		for _, vol := range e.spec.Docker.Volumes {
-			if vol.EmptyDir == nil {
+			if vol.HostPath != nil || vol.External {
 				continue
 			}
 
+			driver := vol.Driver
+			if driver == "" {
+				driver = "local"
+			}
+
 			_, err := e.client.VolumeCreate(ctx, volume.VolumesCreateBody{
-				Name:   vol.Metadata.UID,
-				Driver: "local",
-				Labels: e.spec.Metadata.Labels,
+				Name:       vol.Metadata.UID,
+				Driver:     driver,
+				DriverOpts: vol.DriverOpts,
+				Labels:     e.spec.Metadata.Labels,
 			})
 			if err != nil {
 				return err
@@ -66,12 +97,11 @@ func (e *dockerEngine) Create(ctx context.Context, step *engine.Step) error {
 		return err
 	}
 
-	// create pull options with encoded authorization credentials.
-	pullopts := types.ImagePullOptions{}
-	auth, ok := engine.LookupAuth(e.spec, domain)
-	if ok {
-		pullopts.RegistryAuth = authutil.Encode(auth.Username, auth.Password)
-	}
+	// gather every credential that might authorize a pull against this
+	// registry domain - the auth embedded in the spec plus anything
+	// resolvable via a Docker credential helper - so we can fall back
+	// if the first candidate is rejected.
+	candidates := credentialCandidates(e.spec, domain)
 
 	// automatically pull the latest version of the image if requested
 	// by the process configuration.
@@ -79,13 +109,8 @@ func (e *dockerEngine) Create(ctx context.Context, step *engine.Step) error {
 		(step.Docker.PullPolicy == engine.PullDefault && latest) {
 		// TODO(bradrydzewski) implement the PullDefault strategy to pull
 		// the image if the tag is :latest
-		rc, perr := e.client.ImagePull(ctx, step.Docker.Image, pullopts)
-		if perr == nil {
-			io.Copy(ioutil.Discard, rc)
-			rc.Close()
-		}
-		if perr != nil {
-			return perr
+		if err := pullImageWithAuth(ctx, e, step, step.Docker.Image, candidates); err != nil {
+			return err
 		}
 	}
 
@@ -99,12 +124,9 @@ func (e *dockerEngine) Create(ctx context.Context, step *engine.Step) error {
 	// automatically pull and try to re-create the image if the
 	// failure is caused because the image does not exist.
 	if docker.IsErrImageNotFound(err) && step.Docker.PullPolicy != engine.PullNever {
-		rc, perr := e.client.ImagePull(ctx, step.Docker.Image, pullopts)
-		if perr != nil {
+		if perr := pullImageWithAuth(ctx, e, step, step.Docker.Image, candidates); perr != nil {
 			return perr
 		}
-		io.Copy(ioutil.Discard, rc)
-		rc.Close()
 
 		// once the image is successfully pulled we attempt to
 		// re-create the container.
@@ -143,20 +165,19 @@ func (e *dockerEngine) Start(ctx context.Context, step *engine.Step) error {
 }
 
 func (e *dockerEngine) Wait(ctx context.Context, step *engine.Step) (*engine.State, error) {
-	wait, errc := e.client.ContainerWait(ctx, step.Metadata.UID, "")
-	select {
-	case <-wait:
-	case <-errc:
+	if step.Metadata.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Metadata.Timeout)
+		defer cancel()
 	}
 
-	info, err := e.client.ContainerInspect(ctx, step.Metadata.UID)
+	info, err := e.waitRunning(ctx, step)
+	if err == context.DeadlineExceeded {
+		return e.killTimedOut(step)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if info.State.Running {
-		// TODO(bradrydewski) if the state is still running
-		// we should call wait again.
-	}
 
 	return &engine.State{
 		Exited:    true,
@@ -165,16 +186,65 @@ func (e *dockerEngine) Wait(ctx context.Context, step *engine.Step) (*engine.Sta
 	}, nil
 }
 
-func (e *dockerEngine) Tail(ctx context.Context, step *engine.Step) (io.ReadCloser, error) {
-	opts := types.ContainerLogsOptions{
-		Follow:     true,
-		ShowStdout: true,
-		ShowStderr: true,
-		Details:    false,
-		Timestamps: false,
+// waitRunning calls ContainerWait repeatedly until the container is
+// no longer running. ContainerWait can wake spuriously while the
+// container keeps running, so a single wakeup is not sufficient to
+// conclude the step has exited.
+func (e *dockerEngine) waitRunning(ctx context.Context, step *engine.Step) (types.ContainerJSON, error) {
+	for {
+		wait, errc := e.client.ContainerWait(ctx, step.Metadata.UID, "")
+		select {
+		case <-wait:
+		case <-errc:
+		case <-ctx.Done():
+			return types.ContainerJSON{}, ctx.Err()
+		}
+
+		info, err := e.client.ContainerInspect(ctx, step.Metadata.UID)
+		if err != nil {
+			return types.ContainerJSON{}, err
+		}
+		if !info.State.Running {
+			return info, nil
+		}
 	}
+}
+
+// killGracePeriod is how long a timed out step is given to exit
+// cleanly after SIGTERM before it is forcibly killed. A var, not a
+// const, so tests can shrink it.
+var killGracePeriod = 10 * time.Second
 
-	logs, err := e.client.ContainerLogs(ctx, step.Metadata.UID, opts)
+// killTimedOut terminates a step that exceeded its deadline, sending
+// SIGTERM and escalating to SIGKILL after a grace period, and reports
+// the resulting state with TimedOut set. It uses a background context
+// since the step's own context has already expired.
+func (e *dockerEngine) killTimedOut(step *engine.Step) (*engine.State, error) {
+	ctx := context.Background()
+
+	e.client.ContainerKill(ctx, step.Metadata.UID, "TERM")
+	time.Sleep(killGracePeriod)
+	e.client.ContainerKill(ctx, step.Metadata.UID, "KILL")
+
+	info, err := e.client.ContainerInspect(ctx, step.Metadata.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &engine.State{
+		Exited:    true,
+		ExitCode:  info.State.ExitCode,
+		OOMKilled: info.State.OOMKilled,
+		TimedOut:  true,
+	}, nil
+}
+
+// Tail returns the step's combined stdout and stderr as a single
+// stream, muxing the two together. It is kept for callers that don't
+// care about stream identity; TailStreams exposes stdout and stderr
+// separately.
+func (e *dockerEngine) Tail(ctx context.Context, step *engine.Step) (io.ReadCloser, error) {
+	logs, err := e.client.ContainerLogs(ctx, step.Metadata.UID, tailLogOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -189,6 +259,38 @@ func (e *dockerEngine) Tail(ctx context.Context, step *engine.Step) (io.ReadClos
 	return rc, nil
 }
 
+// TailStreams returns the step's stdout and stderr as separate
+// streams, so callers can label lines, color stderr, or ship each
+// stream to a different log sink instead of receiving them muxed
+// together as Tail does.
+func (e *dockerEngine) TailStreams(ctx context.Context, step *engine.Step) (stdout, stderr io.ReadCloser, err error) {
+	logs, err := e.client.ContainerLogs(ctx, step.Metadata.UID, tailLogOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outR, outW := io.Pipe()
+	errR, errW := io.Pipe()
+
+	go func() {
+		stdcopy.StdCopy(outW, errW, logs)
+		logs.Close()
+		outW.Close()
+		errW.Close()
+	}()
+	return outR, errR, nil
+}
+
+// tailLogOptions is shared by Tail and TailStreams, which differ only
+// in how they demux the same underlying log stream.
+var tailLogOptions = types.ContainerLogsOptions{
+	Follow:     true,
+	ShowStdout: true,
+	ShowStderr: true,
+	Details:    false,
+	Timestamps: false,
+}
+
 func (e *dockerEngine) Destroy(ctx context.Context) error {
 	removeOpts := types.ContainerRemoveOptions{
 		Force:         true,
@@ -202,10 +304,11 @@ func (e *dockerEngine) Destroy(ctx context.Context) error {
 		e.client.ContainerRemove(ctx, step.Metadata.UID, removeOpts)
 	}
 
-	// cleanup all volumes
+	// cleanup all volumes we created, leaving bind mounts and
+	// externally managed volumes untouched
 	if e.spec.Docker != nil {
 		for _, vol := range e.spec.Docker.Volumes {
-			if vol.EmptyDir == nil {
+			if vol.HostPath != nil || vol.External {
 				continue
 			}
 			err := e.client.VolumeRemove(ctx, vol.Metadata.UID, true)
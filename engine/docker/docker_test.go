@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drone/drone-runtime/engine"
+
+	"docker.io/go-docker"
+	"docker.io/go-docker/api/types"
+	"docker.io/go-docker/api/types/container"
+)
+
+// fakeAPIClient embeds docker.APIClient so tests only need to
+// implement the handful of methods waitRunning and killTimedOut
+// actually call; anything else panics if a test exercises it.
+type fakeAPIClient struct {
+	docker.APIClient
+
+	inspectResults []types.ContainerJSON
+	inspectCalls   int
+	killed         []string
+}
+
+func (f *fakeAPIClient) ContainerWait(ctx context.Context, id string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+	wait := make(chan container.ContainerWaitOKBody, 1)
+	wait <- container.ContainerWaitOKBody{}
+	return wait, make(chan error, 1)
+}
+
+func (f *fakeAPIClient) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	result := f.inspectResults[f.inspectCalls]
+	if f.inspectCalls < len(f.inspectResults)-1 {
+		f.inspectCalls++
+	}
+	return result, nil
+}
+
+func (f *fakeAPIClient) ContainerKill(ctx context.Context, id, signal string) error {
+	f.killed = append(f.killed, signal)
+	return nil
+}
+
+func TestWaitRunningRewaitsOnSpuriousWakeup(t *testing.T) {
+	running := types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{}}
+	running.State = &types.ContainerState{Running: true}
+	stopped := types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{}}
+	stopped.State = &types.ContainerState{Running: false, ExitCode: 3}
+
+	client := &fakeAPIClient{inspectResults: []types.ContainerJSON{running, running, stopped}}
+	e := &dockerEngine{client: client}
+	step := &engine.Step{Metadata: engine.Metadata{UID: "step-1"}}
+
+	info, err := e.waitRunning(context.Background(), step)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.State.Running {
+		t.Fatal("waitRunning returned while the container was still running")
+	}
+	if info.State.ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d", info.State.ExitCode)
+	}
+	if client.inspectCalls != 2 {
+		t.Fatalf("expected waitRunning to re-wait past the two spurious wakeups, inspected %d times", client.inspectCalls+1)
+	}
+}
+
+func TestKillTimedOutSendsTermThenKill(t *testing.T) {
+	orig := killGracePeriod
+	killGracePeriod = time.Millisecond
+	defer func() { killGracePeriod = orig }()
+
+	stopped := types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{}}
+	stopped.State = &types.ContainerState{ExitCode: 137}
+
+	client := &fakeAPIClient{inspectResults: []types.ContainerJSON{stopped}}
+	e := &dockerEngine{client: client}
+	step := &engine.Step{Metadata: engine.Metadata{UID: "step-1"}}
+
+	state, err := e.killTimedOut(step)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.TimedOut {
+		t.Fatal("expected TimedOut to be set")
+	}
+	if got := client.killed; len(got) != 2 || got[0] != "TERM" || got[1] != "KILL" {
+		t.Fatalf("expected TERM then KILL, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/drone/drone-runtime/engine"
+	"github.com/drone/drone-runtime/engine/docker/authutil"
+
+	"docker.io/go-docker"
+	"docker.io/go-docker/api/types"
+	"docker.io/go-docker/pkg/jsonmessage"
+)
+
+// pullImage pulls the named image and decodes the resulting JSON
+// message stream, forwarding progress to the spec's configured
+// engine.ProgressReporter, if any.
+func pullImage(ctx context.Context, e *dockerEngine, step *engine.Step, image string, opts types.ImagePullOptions) error {
+	rc, err := e.client.ImagePull(ctx, image, opts)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return decodePull(ctx, e.spec, step, rc)
+}
+
+// pullImageWithAuth pulls the named image, trying each candidate
+// credential in turn, or anonymously if none are given. It moves on to
+// the next candidate only when the registry rejects the credential as
+// unauthorized; any other error is returned at once. Each attempt is
+// retried with backoff through withPullRetry.
+func pullImageWithAuth(ctx context.Context, e *dockerEngine, step *engine.Step, image string, candidates []engine.Auth) error {
+	if len(candidates) == 0 {
+		return e.withPullRetry(ctx, func() error {
+			return pullImage(ctx, e, step, image, types.ImagePullOptions{})
+		})
+	}
+
+	var err error
+	for _, auth := range candidates {
+		opts := types.ImagePullOptions{
+			RegistryAuth: authutil.Encode(auth.Username, auth.Password),
+		}
+		err = e.withPullRetry(ctx, func() error {
+			return pullImage(ctx, e, step, image, opts)
+		})
+		if err == nil {
+			return nil
+		}
+		if !docker.IsErrUnauthorized(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// decodePull reads a Docker JSON message stream, publishing an
+// engine.ProgressEvent for every status update.
+func decodePull(ctx context.Context, spec *engine.Spec, step *engine.Step, rc io.Reader) error {
+	reporter, _ := engine.LookupProgressReporter(spec)
+
+	dec := json.NewDecoder(rc)
+	for {
+		var msg jsonmessage.JSONMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if reporter == nil {
+			continue
+		}
+
+		event := engine.ProgressEvent{
+			Step:   step.Metadata.UID,
+			Layer:  msg.ID,
+			Status: msg.Status,
+		}
+		if msg.Progress != nil {
+			event.Current = msg.Progress.Current
+			event.Total = msg.Progress.Total
+		}
+		reporter.Report(ctx, event)
+	}
+}
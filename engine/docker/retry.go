@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/drone/drone-runtime/engine"
+
+	"docker.io/go-docker"
+)
+
+const (
+	defaultMaxConcurrentPulls = 4
+	defaultMaxPullAttempts    = 5
+
+	pullBackoffBase  = 500 * time.Millisecond
+	pullBackoffLimit = 30 * time.Second
+)
+
+// pullConcurrency returns the configured cap on in-flight ImagePull
+// calls for spec, falling back to defaultMaxConcurrentPulls.
+func pullConcurrency(spec *engine.Spec) int {
+	if spec.Docker != nil && spec.Docker.MaxConcurrentPulls > 0 {
+		return spec.Docker.MaxConcurrentPulls
+	}
+	return defaultMaxConcurrentPulls
+}
+
+// maxPullAttempts returns the configured attempt cap for e's spec,
+// falling back to defaultMaxPullAttempts.
+func (e *dockerEngine) maxPullAttempts() int {
+	if e.spec.Docker != nil && e.spec.Docker.PullRetries > 0 {
+		return e.spec.Docker.PullRetries
+	}
+	return defaultMaxPullAttempts
+}
+
+// withPullRetry runs pull, retrying with backoff while the failure is
+// retryable and returning terminal errors, like a missing manifest, on
+// the first attempt. Concurrent pulls are throttled by e.pullSem.
+func (e *dockerEngine) withPullRetry(ctx context.Context, pull func() error) error {
+	e.pullSem <- struct{}{}
+	defer func() { <-e.pullSem }()
+
+	var err error
+	for attempt := 0; attempt < e.maxPullAttempts(); attempt++ {
+		if err = pull(); err == nil {
+			return nil
+		}
+		if !isRetryablePullError(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(pullBackoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// pullBackoff returns a jittered exponential backoff duration for the
+// given zero-indexed attempt, capped at pullBackoffLimit.
+func pullBackoff(attempt int) time.Duration {
+	d := pullBackoffBase * time.Duration(int64(1)<<uint(attempt))
+	if d > pullBackoffLimit {
+		d = pullBackoffLimit
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// isRetryablePullError reports whether a failed pull is worth
+// retrying. A missing manifest or an unauthorized response won't.
+func isRetryablePullError(err error) bool {
+	return !docker.IsErrNotFound(err) && !docker.IsErrUnauthorized(err)
+}
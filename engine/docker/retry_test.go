@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPullBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := pullBackoff(attempt)
+		if d <= 0 {
+			t.Fatalf("attempt %d: backoff must be positive, got %s", attempt, d)
+		}
+		if d > pullBackoffLimit {
+			t.Fatalf("attempt %d: backoff %s exceeds limit %s", attempt, d, pullBackoffLimit)
+		}
+	}
+
+	if d := pullBackoff(0); d > pullBackoffBase {
+		t.Fatalf("first attempt backoff %s should not exceed the base %s", d, pullBackoffBase)
+	}
+}
+
+func TestIsRetryablePullError(t *testing.T) {
+	if isRetryablePullError(errors.New("connection reset by peer")) != true {
+		t.Fatal("a generic transport error should be retryable")
+	}
+}
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"github.com/drone/drone-runtime/engine"
+
+	"docker.io/go-docker/api/types/container"
+	"docker.io/go-docker/api/types/mount"
+)
+
+// toHostConfig builds a container's HostConfig, translating each
+// volume a step mounts into the mount type appropriate for its kind:
+// an emptyDir or named volume backed by a (possibly external, non-
+// local) Docker volume driver, or a host path bind mount.
+func toHostConfig(spec *engine.Spec, step *engine.Step) *container.HostConfig {
+	return &container.HostConfig{
+		Mounts: toMounts(spec, step),
+	}
+}
+
+func toMounts(spec *engine.Spec, step *engine.Step) []mount.Mount {
+	var mounts []mount.Mount
+	if spec.Docker == nil {
+		return mounts
+	}
+	for _, m := range step.Docker.Volumes {
+		vol, ok := engine.LookupVolume(spec, m.Name)
+		if !ok {
+			continue
+		}
+
+		if vol.HostPath != nil {
+			mounts = append(mounts, mount.Mount{
+				Type:     mount.TypeBind,
+				Source:   vol.HostPath.Path,
+				Target:   m.Path,
+				ReadOnly: m.ReadOnly,
+			})
+			continue
+		}
+
+		// both emptyDir and named volumes are backed by a Docker
+		// volume of the same UID, created in Setup (or pre-existing,
+		// for external volumes); only the driver differs.
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   vol.Metadata.UID,
+			Target:   m.Path,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+	return mounts
+}
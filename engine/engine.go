@@ -0,0 +1,64 @@
+// Package engine defines the pipeline execution primitives shared by
+// every backend driver (Docker, Kubernetes, Nomad, ...). A driver
+// implements the Engine interface and registers a constructor with
+// RegisterDriver so it can be selected by name at runtime.
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrMissingConfig is returned by the Kubernetes and Nomad drivers'
+// Create methods when a step carries no Docker configuration. The
+// Docker driver returns its own error for the same case.
+var ErrMissingConfig = errors.New("engine: missing configuration")
+
+// Engine executes the steps of a single pipeline run. A new Engine is
+// created per run by a Driver.
+type Engine interface {
+	// Setup provisions pipeline-wide resources, such as volumes and
+	// networks, before any step runs.
+	Setup(ctx context.Context) error
+
+	// Create prepares a step to run, without starting it.
+	Create(ctx context.Context, step *Step) error
+
+	// Start starts a step previously prepared with Create.
+	Start(ctx context.Context, step *Step) error
+
+	// Wait blocks until the step exits and returns its terminal state.
+	Wait(ctx context.Context, step *Step) (*State, error)
+
+	// Tail streams the step's combined stdout and stderr.
+	Tail(ctx context.Context, step *Step) (io.ReadCloser, error)
+
+	// Destroy tears down every resource created for the pipeline.
+	Destroy(ctx context.Context) error
+}
+
+// Driver creates a new Engine for the given pipeline spec.
+type Driver func(spec *Spec) (Engine, error)
+
+var (
+	driversMu sync.Mutex
+	drivers   = map[string]Driver{}
+)
+
+// RegisterDriver makes a Driver available under name so it can be
+// selected as a pipeline's backend at runtime.
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driver
+}
+
+// LookupDriver returns the Driver registered under name, if any.
+func LookupDriver(name string) (Driver, bool) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	driver, ok := drivers[name]
+	return driver, ok
+}
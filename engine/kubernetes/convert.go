@@ -0,0 +1,84 @@
+package kubernetes
+
+import (
+	"github.com/drone/drone-runtime/engine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// toPod converts a step definition to a Kubernetes Pod spec, mounting
+// the emptyDir volumes declared in the pipeline spec and attaching
+// the environment and command from the step's Docker configuration.
+// Host path and named volumes are not mounted.
+func toPod(spec *engine.Spec, step *engine.Step) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   step.Metadata.UID,
+			Labels: spec.Metadata.Labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				toContainer(spec, step),
+			},
+			Volumes: toVolumes(spec),
+		},
+	}
+}
+
+func toContainer(spec *engine.Spec, step *engine.Step) corev1.Container {
+	return corev1.Container{
+		Name:         step.Metadata.UID,
+		Image:        step.Docker.Image,
+		Command:      step.Docker.Entrypoint,
+		Args:         step.Docker.Command,
+		Env:          toEnv(step),
+		VolumeMounts: toVolumeMounts(spec, step),
+	}
+}
+
+func toEnv(step *engine.Step) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for k, v := range step.Envs {
+		env = append(env, corev1.EnvVar{Name: k, Value: v})
+	}
+	return env
+}
+
+func toVolumes(spec *engine.Spec) []corev1.Volume {
+	var volumes []corev1.Volume
+	if spec.Docker == nil {
+		return volumes
+	}
+	for _, vol := range spec.Docker.Volumes {
+		if vol.EmptyDir == nil {
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: vol.Metadata.UID,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: vol.Metadata.UID,
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+func toVolumeMounts(spec *engine.Spec, step *engine.Step) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, m := range step.Docker.Volumes {
+		vol, ok := engine.LookupVolume(spec, m.Name)
+		if !ok || vol.EmptyDir == nil {
+			continue
+		}
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      vol.Metadata.UID,
+			MountPath: m.Path,
+			ReadOnly:  m.ReadOnly,
+		})
+	}
+	return mounts
+}
@@ -0,0 +1,165 @@
+package kubernetes
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/drone/drone-runtime/engine"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	engine.RegisterDriver("kubernetes", New)
+}
+
+// New returns a new engine.Engine that maps a pipeline onto
+// Kubernetes primitives. Each step in the pipeline is scheduled as a
+// Pod in the spec's namespace, with volumes and the pod network
+// translated from the generic engine.Spec.
+func New(spec *engine.Spec) (engine.Engine, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeEngine{
+		spec:      spec,
+		client:    client,
+		namespace: spec.Metadata.Namespace,
+	}, nil
+}
+
+// loadConfig resolves the Kubernetes client config, preferring
+// in-cluster configuration and falling back to the default kubeconfig
+// on the host running the runtime.
+func loadConfig() (*rest.Config, error) {
+	if config, err := rest.InClusterConfig(); err == nil {
+		return config, nil
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+}
+
+type kubeEngine struct {
+	spec      *engine.Spec
+	client    kubernetes.Interface
+	namespace string
+}
+
+func (e *kubeEngine) Setup(ctx context.Context) error {
+	// every step shares the pipeline's pod network by virtue of
+	// running in the same namespace; emptyDir volumes declared on the
+	// spec are materialized as PersistentVolumeClaims so that pods
+	// created for later steps can mount data written by earlier ones.
+	// Host path and named volumes are not provisioned.
+	if e.spec.Docker == nil {
+		return nil
+	}
+	for _, vol := range e.spec.Docker.Volumes {
+		if vol.EmptyDir == nil {
+			continue
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   vol.Metadata.UID,
+				Labels: e.spec.Metadata.Labels,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			},
+		}
+		if _, err := e.client.CoreV1().PersistentVolumeClaims(e.namespace).Create(pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *kubeEngine) Create(ctx context.Context, step *engine.Step) error {
+	if step.Docker == nil {
+		return engine.ErrMissingConfig
+	}
+	pod := toPod(e.spec, step)
+	_, err := e.client.CoreV1().Pods(e.namespace).Create(pod)
+	return err
+}
+
+func (e *kubeEngine) Start(ctx context.Context, step *engine.Step) error {
+	// pods transition to Running as soon as they are scheduled and
+	// their containers start; there is no separate start call in the
+	// Kubernetes API, so this is a no-op retained to satisfy the
+	// engine.Engine interface.
+	return nil
+}
+
+func (e *kubeEngine) Wait(ctx context.Context, step *engine.Step) (*engine.State, error) {
+	watcher, err := e.client.CoreV1().Pods(e.namespace).Watch(metav1.ListOptions{
+		FieldSelector: "metadata.name=" + step.Metadata.UID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Stop()
+
+	for event := range watcher.ResultChan() {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok || len(pod.Status.ContainerStatuses) == 0 {
+			continue
+		}
+		term := pod.Status.ContainerStatuses[0].State.Terminated
+		if term == nil {
+			continue
+		}
+		return &engine.State{
+			Exited:   true,
+			ExitCode: int(term.ExitCode),
+		}, nil
+	}
+	return nil, ctx.Err()
+}
+
+func (e *kubeEngine) Tail(ctx context.Context, step *engine.Step) (io.ReadCloser, error) {
+	opts := &corev1.PodLogOptions{Follow: true}
+	return e.client.CoreV1().Pods(e.namespace).GetLogs(step.Metadata.UID, opts).Stream()
+}
+
+// TailStreams returns the pod's combined log as stdout, with stderr
+// always empty. The Kubernetes logs API interleaves stdout and stderr
+// into a single stream with no reliable way to demux them back out, so
+// callers that need genuinely separate streams should use the Docker
+// or Nomad drivers instead.
+func (e *kubeEngine) TailStreams(ctx context.Context, step *engine.Step) (stdout, stderr io.ReadCloser, err error) {
+	stdout, err = e.Tail(ctx, step)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stdout, ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+func (e *kubeEngine) Destroy(ctx context.Context) error {
+	for _, step := range e.spec.Steps {
+		e.client.CoreV1().Pods(e.namespace).Delete(step.Metadata.UID, &metav1.DeleteOptions{})
+	}
+	if e.spec.Docker != nil {
+		for _, vol := range e.spec.Docker.Volumes {
+			if vol.EmptyDir == nil {
+				continue
+			}
+			e.client.CoreV1().PersistentVolumeClaims(e.namespace).Delete(vol.Metadata.UID, &metav1.DeleteOptions{})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package nomad
+
+import (
+	"github.com/drone/drone-runtime/engine"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// toJob converts a step definition into a single-task batch job that
+// runs the step's image via Nomad's "docker" task driver.
+func toJob(spec *engine.Spec, step *engine.Step) *api.Job {
+	job := api.NewBatchJob(step.Metadata.UID, step.Metadata.UID, "global", 50)
+	job.TaskGroups = []*api.TaskGroup{
+		{
+			Name:  &step.Metadata.UID,
+			Count: intPtr(1),
+			Tasks: []*api.Task{
+				toTask(spec, step),
+			},
+		},
+	}
+	return job
+}
+
+func toTask(spec *engine.Spec, step *engine.Step) *api.Task {
+	return &api.Task{
+		Name:   step.Metadata.UID,
+		Driver: "docker",
+		Config: map[string]interface{}{
+			"image":   step.Docker.Image,
+			"command": firstOrEmpty(step.Docker.Entrypoint),
+			"args":    step.Docker.Command,
+		},
+		Env: step.Envs,
+	}
+}
+
+func firstOrEmpty(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,156 @@
+package nomad
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/drone/drone-runtime/engine"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// waitPollInterval is how often Wait re-polls allocation status while
+// no allocation has reached a terminal state yet.
+const waitPollInterval = 2 * time.Second
+
+func init() {
+	engine.RegisterDriver("nomad", New)
+}
+
+// New returns a new engine.Engine that schedules each step as a
+// single-task Nomad job, using the "docker" Nomad task driver under
+// the hood so that existing step images run unmodified.
+func New(spec *engine.Spec) (engine.Engine, error) {
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &nomadEngine{
+		spec:   spec,
+		client: client,
+	}, nil
+}
+
+type nomadEngine struct {
+	spec   *engine.Spec
+	client *api.Client
+}
+
+func (e *nomadEngine) Setup(ctx context.Context) error {
+	// Nomad has no namespaced network or volume primitives to
+	// pre-provision, and steps are scheduled without volume mounts,
+	// so there is nothing to create up front.
+	return nil
+}
+
+func (e *nomadEngine) Create(ctx context.Context, step *engine.Step) error {
+	if step.Docker == nil {
+		return engine.ErrMissingConfig
+	}
+	job := toJob(e.spec, step)
+	_, _, err := e.client.Jobs().Register(job, nil)
+	return err
+}
+
+func (e *nomadEngine) Start(ctx context.Context, step *engine.Step) error {
+	// the job is already dispatched to the scheduler at registration
+	// time, so there is no separate start step.
+	return nil
+}
+
+func (e *nomadEngine) Wait(ctx context.Context, step *engine.Step) (*engine.State, error) {
+	for {
+		evals, _, err := e.client.Jobs().Evaluations(step.Metadata.UID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, eval := range evals {
+			allocs, _, err := e.client.Evaluations().Allocations(eval.ID, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, alloc := range allocs {
+				if alloc.ClientStatus == api.AllocClientStatusComplete ||
+					alloc.ClientStatus == api.AllocClientStatusFailed {
+					return &engine.State{
+						Exited:   true,
+						ExitCode: exitCode(alloc),
+					}, nil
+				}
+			}
+		}
+
+		select {
+		case <-time.After(waitPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Tail returns the task's stdout only; stderr is not included in the
+// stream. TailStreams returns both.
+func (e *nomadEngine) Tail(ctx context.Context, step *engine.Step) (io.ReadCloser, error) {
+	alloc, _, err := e.client.Allocations().Info(step.Metadata.UID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.tailTask(alloc, step, "stdout")
+}
+
+// TailStreams returns the step's stdout and stderr as separate
+// streams. Nomad's AllocFS API already tracks the two independently
+// per task, so this is the same call as Tail made twice with a
+// different log type.
+func (e *nomadEngine) TailStreams(ctx context.Context, step *engine.Step) (stdout, stderr io.ReadCloser, err error) {
+	alloc, _, err := e.client.Allocations().Info(step.Metadata.UID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err = e.tailTask(alloc, step, "stdout")
+	if err != nil {
+		return nil, nil, err
+	}
+	stderr, err = e.tailTask(alloc, step, "stderr")
+	if err != nil {
+		stdout.Close()
+		return nil, nil, err
+	}
+	return stdout, stderr, nil
+}
+
+// tailTask streams the given log type ("stdout" or "stderr") for the
+// step's task out of the allocation's filesystem.
+func (e *nomadEngine) tailTask(alloc *api.Allocation, step *engine.Step, logType string) (io.ReadCloser, error) {
+	rc, err := e.client.AllocFS().Logs(alloc, true, step.Metadata.UID, logType, "end", 0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		for frame := range rc {
+			pw.Write(frame.Data)
+		}
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func (e *nomadEngine) Destroy(ctx context.Context) error {
+	for _, step := range e.spec.Steps {
+		if _, _, err := e.client.Jobs().Deregister(step.Metadata.UID, true, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitCode maps the allocation's client status to an exit code: 0
+// when it completed, 1 otherwise. The task's own exit code is not read.
+func exitCode(alloc *api.AllocationListStub) int {
+	if alloc.ClientStatus == api.AllocClientStatusComplete {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,27 @@
+package engine
+
+import "context"
+
+// ProgressEvent reports pull progress for a single image layer.
+type ProgressEvent struct {
+	Step    string
+	Layer   string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// ProgressReporter receives a ProgressEvent for every layer update as
+// an image pull progresses.
+type ProgressReporter interface {
+	Report(ctx context.Context, event ProgressEvent)
+}
+
+// LookupProgressReporter returns the ProgressReporter configured on
+// spec, if any.
+func LookupProgressReporter(spec *Spec) (ProgressReporter, bool) {
+	if spec.Progress == nil {
+		return nil, false
+	}
+	return spec.Progress, true
+}
@@ -0,0 +1,90 @@
+package engine
+
+import "time"
+
+// Metadata carries identifying information shared by a Spec and its
+// Steps: the resource's unique ID, the namespace it runs in, and any
+// labels the backend driver should propagate.
+type Metadata struct {
+	UID       string
+	Namespace string
+	Labels    map[string]string
+
+	// Timeout bounds how long a step may run before it is killed.
+	// Zero means no timeout. Only meaningful on a Step's Metadata.
+	Timeout time.Duration
+}
+
+// Spec describes a pipeline: its steps and the configuration shared
+// across all of them.
+type Spec struct {
+	Metadata Metadata
+	Docker   *DockerSpec
+	Steps    []*Step
+
+	// Progress, if set, receives image pull progress events.
+	Progress ProgressReporter
+}
+
+// Step describes a single unit of work in a pipeline.
+type Step struct {
+	Metadata Metadata
+	Docker   *StepDocker
+	Envs     map[string]string
+}
+
+// DockerSpec holds pipeline-wide configuration for backends that run
+// steps as containers. It is not exclusive to the Docker driver -
+// the Kubernetes driver reuses its volumes too, since a volume means
+// the same thing regardless of which scheduler ends up placing the
+// container. The Nomad driver does not read it.
+type DockerSpec struct {
+	Volumes []*Volume
+
+	// Auths are the registry credentials embedded directly in the
+	// spec, checked before falling back to a credential helper.
+	Auths []Auth
+
+	// PullRetries caps how many attempts are made to pull an image,
+	// counting the first one. Zero uses the driver's default.
+	PullRetries int
+
+	// MaxConcurrentPulls caps how many ImagePull calls may be in
+	// flight at once across all steps of the pipeline. Zero uses the
+	// driver's default.
+	MaxConcurrentPulls int
+}
+
+// StepDocker holds the container configuration for a single step.
+type StepDocker struct {
+	Image      string
+	Entrypoint []string
+	Command    []string
+	PullPolicy PullPolicy
+	Volumes    []*VolumeMount
+}
+
+// PullPolicy controls when a step's image is pulled before it runs.
+type PullPolicy int
+
+const (
+	// PullDefault pulls the image only if it is missing locally,
+	// unless the tag is :latest.
+	PullDefault PullPolicy = iota
+	// PullAlways always pulls the image before running the step.
+	PullAlways
+	// PullNever never pulls the image; the step fails if it is
+	// missing locally.
+	PullNever
+)
+
+// State is the terminal state of a step once it has exited.
+type State struct {
+	Exited    bool
+	ExitCode  int
+	OOMKilled bool
+
+	// TimedOut reports whether the step was killed for exceeding its
+	// Metadata.Timeout rather than exiting on its own.
+	TimedOut bool
+}
@@ -0,0 +1,52 @@
+package engine
+
+// Volume describes a volume made available to steps: an emptyDir
+// scoped to the pipeline, a host path bind mount, or a named volume
+// created (or, if External, expected to already exist) with Driver.
+type Volume struct {
+	Metadata Metadata
+	Name     string
+	EmptyDir *EmptyDirVolume
+	HostPath *HostPathVolume
+
+	// External marks a named volume as managed outside the pipeline;
+	// it is expected to already exist and is left untouched by
+	// Destroy.
+	External bool
+
+	// Driver and DriverOpts configure the volume driver used to
+	// create a named volume. Driver defaults to "local" when empty.
+	Driver     string
+	DriverOpts map[string]string
+}
+
+// EmptyDirVolume is a volume created empty by Setup and discarded
+// on Destroy.
+type EmptyDirVolume struct{}
+
+// HostPathVolume binds a path on the host running the step directly
+// into the container.
+type HostPathVolume struct {
+	Path string
+}
+
+// VolumeMount attaches a Volume, by name, to a Step at Path.
+type VolumeMount struct {
+	Name     string
+	Path     string
+	ReadOnly bool
+}
+
+// LookupVolume returns the Volume declared on spec whose Name matches
+// name, if any.
+func LookupVolume(spec *Spec, name string) (*Volume, bool) {
+	if spec.Docker == nil {
+		return nil, false
+	}
+	for _, vol := range spec.Docker.Volumes {
+		if vol.Name == name {
+			return vol, true
+		}
+	}
+	return nil, false
+}